@@ -0,0 +1,212 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package postgrescluster implements the controller that reconciles
+// PostgresCluster custom resources into running PostgreSQL clusters.
+package postgrescluster
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/trace"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1alpha1"
+)
+
+// CrunchyPostgresHAImage is the default container image used for
+// PostgreSQL instances when a PostgresCluster does not specify one.
+const CrunchyPostgresHAImage = "registry.developers.crunchydata.com/crunchydata/crunchy-postgres-ha:centos8-13.3-0"
+
+// PodExecutor runs a command in a running container and streams its
+// stdin/stdout/stderr, much like "kubectl exec".
+type PodExecutor func(
+	namespace, pod, container string, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+) error
+
+// Reconciler reconciles a PostgresCluster object by driving the Kubernetes
+// objects that make up the cluster toward the desired state described by its
+// spec, including teardown when the cluster is deleted.
+type Reconciler struct {
+	Client   client.Client
+	Owner    client.FieldOwner
+	Recorder record.EventRecorder
+	Tracer   trace.Tracer
+
+	// PodExec execs into a Pod's container; it is used to talk to Patroni
+	// and pgBackRest without opening a network port to the cluster.
+	PodExec PodExecutor
+
+	// DeletionMode selects how child objects are tied to the lifecycle of a
+	// PostgresCluster. It defaults to DeletionModeFinalizer, matching
+	// existing deployments; set it from DeletionModeFromEnv at startup to
+	// honor PGO_DELETION_MODE.
+	DeletionMode DeletionMode
+
+	// WatchFilterValue scopes the controller to PostgresClusters carrying a
+	// naming.LabelWatchFilter label equal to this value. Leave it empty
+	// (the default) to watch every PostgresCluster; set it from
+	// WatchFilterValueFromEnv to shard a namespace between multiple
+	// operator instances.
+	WatchFilterValue string
+}
+
+// Reconcile implements the main control loop for PostgresCluster. It brings
+// the running cluster in line with the spec, or, when the cluster carries a
+// non-zero DeletionTimestamp, tears the cluster down.
+func (r *Reconciler) Reconcile(
+	ctx context.Context, request reconcile.Request,
+) (reconcile.Result, error) {
+	ctx, span := r.Tracer.Start(ctx, "Reconcile")
+	defer span.End()
+
+	cluster := &v1alpha1.PostgresCluster{}
+	if err := r.Client.Get(ctx, request.NamespacedName, cluster); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Deletion takes priority over the pause annotation: a cluster that is
+	// paused must still be allowed to tear down, or its finalizer (and any
+	// namespace delete waiting on it) would hang until someone notices and
+	// manually clears the annotation.
+	if !cluster.DeletionTimestamp.IsZero() {
+		if r.DeletionMode == DeletionModeOwnerReferences {
+			return r.handleDeleteOwnerReferences(ctx, cluster)
+		}
+		return r.handleDelete(ctx, cluster)
+	}
+
+	if cluster.Annotations[naming.AnnotationPaused] == "true" {
+		return reconcile.Result{}, r.setPausedCondition(ctx, cluster)
+	}
+
+	if r.DeletionMode == DeletionModeOwnerReferences {
+		if err := r.reconcileOwnerReferences(ctx, cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err := r.reconcileFinalizer(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Reconcile stops here: it does not create or update the StatefulSets,
+	// Services, Secrets, or ConfigMaps that make up a running cluster, and
+	// nothing else in this package does either.
+	return reconcile.Result{}, nil
+}
+
+// setPausedCondition records on cluster's status that reconciliation is
+// short-circuited because of its naming.AnnotationPaused annotation.
+func (r *Reconciler) setPausedCondition(ctx context.Context, cluster *v1alpha1.PostgresCluster) error {
+	if condition := apimeta.FindStatusCondition(cluster.Status.Conditions, "Paused"); condition != nil &&
+		condition.Status == metav1.ConditionTrue &&
+		condition.ObservedGeneration == cluster.Generation {
+		return nil
+	}
+
+	before := cluster.DeepCopy()
+	apimeta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               "Paused",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.Generation,
+		Reason:             "Paused",
+		Message:            "Reconciliation is paused via the " + naming.AnnotationPaused + " annotation.",
+	})
+	return r.Client.Status().Patch(ctx, cluster, client.MergeFrom(before), r.Owner)
+}
+
+// instanceStatefulSets returns the StatefulSets that back cluster's instance
+// sets, labeled with naming.LabelCluster and naming.LabelInstance.
+func (r *Reconciler) instanceStatefulSets(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) (*appsv1.StatefulSetList, error) {
+	list := &appsv1.StatefulSetList{}
+	selector, err := instanceSelector(cluster)
+	if err != nil {
+		return nil, err
+	}
+	err = r.Client.List(ctx, list,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector})
+	return list, err
+}
+
+// SetupWithManager registers the Reconciler with mgr so that changes to
+// PostgresCluster objects (and the objects they own) trigger reconciliation.
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	predicates := r.watchPredicates()
+
+	return builder.ControllerManagedBy(mgr).
+		For(&v1alpha1.PostgresCluster{}, builder.WithPredicates(predicates)).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(predicates)).
+		Complete(r)
+}
+
+// newPodExecutor returns a PodExecutor that execs into Pods of the cluster
+// identified by config using the SPDY protocol.
+func newPodExecutor(config *rest.Config) (PodExecutor, error) {
+	// rest.RESTClientFor requires a GroupVersion and serializer, which a
+	// plain cluster config does not carry; Pods live in the core "v1" group.
+	config = rest.CopyConfig(config)
+	config.APIPath = "/api"
+	config.GroupVersion = &v1.SchemeGroupVersion
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+
+	restClient, err := rest.RESTClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(
+		namespace, pod, container string, stdin io.Reader, stdout, stderr io.Writer,
+		command ...string,
+	) error {
+		request := restClient.Post().
+			Resource("pods").
+			Namespace(namespace).
+			Name(pod).
+			SubResource("exec").
+			VersionedParams(&v1.PodExecOptions{
+				Container: container,
+				Command:   command,
+				Stdin:     stdin != nil,
+				Stdout:    stdout != nil,
+				Stderr:    stderr != nil,
+			}, scheme.ParameterCodec)
+
+		executor, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+		if err != nil {
+			return err
+		}
+
+		return executor.Stream(remotecommand.StreamOptions{
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}, nil
+}
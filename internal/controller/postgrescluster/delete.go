@@ -0,0 +1,575 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1alpha1"
+)
+
+// foregroundDeleteBackoff is how long handleDelete waits between requeues
+// while a foreground cascade deletion is in progress. Foreground deletes
+// wait on Kubernetes garbage collection, which works on the order of
+// seconds, so there is no benefit to polling any faster.
+const foregroundDeleteBackoff = 2 * time.Second
+
+// instanceSelector returns a label selector matching every object that
+// belongs to one of cluster's instance sets.
+func instanceSelector(cluster *v1alpha1.PostgresCluster) (labels.Selector, error) {
+	return labels.Parse(strings.Join([]string{
+		naming.LabelCluster + "=" + cluster.Name,
+		naming.LabelInstance,
+	}, ","))
+}
+
+// patroniDCSSelector returns a label selector matching the Endpoints and
+// ConfigMaps that make up Patroni's distributed configuration store for
+// cluster.
+func patroniDCSSelector(cluster *v1alpha1.PostgresCluster) (labels.Selector, error) {
+	return labels.Parse(strings.Join([]string{
+		naming.LabelCluster + "=" + cluster.Name,
+		naming.LabelPatroni,
+	}, ","))
+}
+
+// deletionPolicy returns the effective deletion policy for cluster, treating
+// a nil spec as "retain nothing" and PreserveOnDelete as shorthand for
+// retaining everything.
+func deletionPolicy(cluster *v1alpha1.PostgresCluster) v1alpha1.PostgresClusterDeletionPolicy {
+	policy := cluster.Spec.DeletionPolicy
+	if policy == nil {
+		return v1alpha1.PostgresClusterDeletionPolicy{}
+	}
+	if policy.PreserveOnDelete {
+		return v1alpha1.PostgresClusterDeletionPolicy{
+			PreserveOnDelete: true,
+			RetainPVCs:       true,
+			RetainSecrets:    true,
+			RetainPatroniDCS: true,
+		}
+	}
+	return *policy
+}
+
+// reconcileFinalizer ensures that cluster carries the operator's finalizer
+// so that handleDelete runs before the object is removed from etcd.
+func (r *Reconciler) reconcileFinalizer(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) error {
+	if controllerutil.ContainsFinalizer(cluster, naming.FinalizerPostgresCluster) {
+		return nil
+	}
+
+	before := cluster.DeepCopy()
+	controllerutil.AddFinalizer(cluster, naming.FinalizerPostgresCluster)
+	return r.Client.Patch(ctx, cluster, client.MergeFrom(before), r.Owner)
+}
+
+// splitInstancesByRole separates instance StatefulSets into the replica set
+// and the (at most one) primary, using naming.LabelRole.
+func splitInstancesByRole(
+	instances *appsv1.StatefulSetList,
+) (replicas []appsv1.StatefulSet, primary *appsv1.StatefulSet) {
+	for i := range instances.Items {
+		sts := &instances.Items[i]
+		if sts.Labels[naming.LabelRole] == naming.RolePatroniLeader {
+			primary = sts
+			continue
+		}
+		replicas = append(replicas, *sts)
+	}
+	return replicas, primary
+}
+
+// isForegroundDeletion reports whether cluster is being deleted with
+// metav1.DeletePropagationForeground, which Kubernetes signals by attaching
+// metav1.FinalizerDeleteDependents to the object.
+func isForegroundDeletion(cluster *v1alpha1.PostgresCluster) bool {
+	return controllerutil.ContainsFinalizer(cluster, metav1.FinalizerDeleteDependents)
+}
+
+// quiescePrimary pauses Patroni's automatic failover on the Pod belonging to
+// the primary StatefulSet so that a foreground delete cannot race a
+// promotion while replicas are torn down underneath it.
+func (r *Reconciler) quiescePrimary(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, primary *appsv1.StatefulSet,
+) error {
+	pod, err := r.instancePod(ctx, cluster, primary)
+	if err != nil || pod == nil {
+		return err
+	}
+
+	executor := patroni.Executor(func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, "database", stdin, stdout, stderr, command...)
+	})
+	return executor.Pause(ctx)
+}
+
+// instancePod returns one running Pod belonging to sts, or nil if none
+// exists yet.
+func (r *Reconciler) instancePod(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, sts *appsv1.StatefulSet,
+) (*v1.Pod, error) {
+	pods := &v1.PodList{}
+	selector, err := labels.Parse(strings.Join([]string{
+		naming.LabelCluster + "=" + cluster.Name,
+		naming.LabelInstance + "=" + sts.Labels[naming.LabelInstance],
+	}, ","))
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Client.List(ctx, pods,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, nil
+	}
+	return &pods.Items[0], nil
+}
+
+// waitForPodGone blocks briefly for sts's Pod to finish terminating. It
+// gives up without error after a short timeout; the caller's requeue will
+// check again on the next reconcile.
+func (r *Reconciler) waitForPodGone(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, sts *appsv1.StatefulSet,
+) error {
+	err := wait.PollImmediate(500*time.Millisecond, 10*time.Second, func() (bool, error) {
+		pod, err := r.instancePod(ctx, cluster, sts)
+		if err != nil {
+			return false, err
+		}
+		return pod == nil, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil
+	}
+	return err
+}
+
+// retainOwnedObject clears OwnerReferences pointing at cluster from obj so
+// that Kubernetes garbage collection leaves it alone, then updates it.
+func (r *Reconciler) retainOwnedObject(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, obj client.Object,
+) error {
+	before := obj.DeepCopyObject().(client.Object)
+
+	owners := obj.GetOwnerReferences()
+	kept := owners[:0]
+	for _, owner := range owners {
+		if owner.UID != cluster.UID {
+			kept = append(kept, owner)
+		}
+	}
+	obj.SetOwnerReferences(kept)
+
+	if len(kept) == len(owners) {
+		return nil
+	}
+	return r.Client.Patch(ctx, obj, client.MergeFrom(before), r.Owner)
+}
+
+// reconcileFinalBackup runs the pre-delete pgBackRest backup required by
+// cluster.Spec.BackupBeforeDelete, if any, and reports its progress on
+// status.conditions. It returns true once the rest of handleDelete is clear
+// to proceed.
+func (r *Reconciler) reconcileFinalBackup(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, primary *appsv1.StatefulSet,
+) (bool, error) {
+	mode := cluster.Spec.BackupBeforeDelete
+	if mode == "" || mode == v1alpha1.BackupBeforeDeleteNever {
+		return true, nil
+	}
+
+	if condition := apimeta.FindStatusCondition(
+		cluster.Status.Conditions, naming.ConditionFinalBackup); condition != nil {
+		switch condition.Reason {
+		case "Succeeded":
+			return true, nil
+		case "Failed":
+			if mode != v1alpha1.BackupBeforeDeleteRequired {
+				return true, nil
+			}
+			// Required backups get another attempt rather than being stuck on
+			// the first failure forever: they are recorded as InProgress
+			// again below and run on the following reconcile.
+		case "InProgress":
+			// The previous reconcile already recorded that a backup is
+			// starting; run it now.
+			return r.takeFinalBackup(ctx, cluster, primary, mode)
+		}
+	}
+
+	if primary == nil {
+		if mode == v1alpha1.BackupBeforeDeleteRequired {
+			if err := r.setFinalBackupCondition(ctx, cluster, metav1.ConditionFalse,
+				"NoPrimary", "Waiting for a primary to back up before deleting the cluster."); err != nil {
+				return false, err
+			}
+		}
+		// There is no primary left to back up.
+		return mode != v1alpha1.BackupBeforeDeleteRequired, nil
+	}
+
+	pod, err := r.instancePod(ctx, cluster, primary)
+	if err != nil {
+		return false, err
+	}
+	if pod == nil {
+		// Wait for the primary Pod to be running.
+		return false, nil
+	}
+
+	if err := r.setFinalBackupCondition(ctx, cluster, metav1.ConditionUnknown,
+		"InProgress", "Taking a final pgBackRest backup before deleting the cluster."); err != nil {
+		return false, err
+	}
+	// Requeue rather than running the backup in this same call, so that
+	// status.conditions reflects "InProgress" for any observer before the
+	// backup actually starts.
+	return false, nil
+}
+
+// takeFinalBackup runs the pgBackRest backup itself, once reconcileFinalBackup
+// has recorded that it is InProgress.
+func (r *Reconciler) takeFinalBackup(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, primary *appsv1.StatefulSet,
+	mode v1alpha1.BackupBeforeDeleteMode,
+) (bool, error) {
+	pod, err := r.instancePod(ctx, cluster, primary)
+	if err != nil {
+		return false, err
+	}
+	if pod == nil {
+		// The primary Pod went away mid-backup; let the caller's "InProgress"
+		// condition be picked up again on the next reconcile.
+		return false, nil
+	}
+
+	executor := pgbackrest.Executor(func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, "database", stdin, stdout, stderr, command...)
+	})
+
+	if backupErr := executor.Backup(ctx, "repo1", pgbackrest.BackupTypeFull); backupErr != nil {
+		r.Recorder.Eventf(cluster, v1.EventTypeWarning, "FinalBackupFailed", "%v", backupErr)
+		if err := r.setFinalBackupCondition(ctx, cluster, metav1.ConditionFalse,
+			"Failed", backupErr.Error()); err != nil {
+			return false, err
+		}
+		return mode == v1alpha1.BackupBeforeDeleteIfPossible, nil
+	}
+
+	if err := r.setFinalBackupCondition(ctx, cluster, metav1.ConditionTrue,
+		"Succeeded", "Final pgBackRest backup completed."); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setFinalBackupCondition records the progress of the final backup on
+// cluster's status.conditions.
+func (r *Reconciler) setFinalBackupCondition(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+	status metav1.ConditionStatus, reason, message string,
+) error {
+	before := cluster.DeepCopy()
+	apimeta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               naming.ConditionFinalBackup,
+		Status:             status,
+		ObservedGeneration: cluster.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return r.Client.Status().Patch(ctx, cluster, client.MergeFrom(before), r.Owner)
+}
+
+// handleDelete tears down everything the operator created for cluster once
+// cluster carries a non-zero DeletionTimestamp, then removes the operator's
+// finalizer so Kubernetes can finish deleting the custom resource. It acts
+// in stages -- replicas, then the primary, then, once their Pods are gone,
+// the Patroni DCS, PVCs, and Secrets together in one pass -- requeueing
+// between stages so long-running deletes do not block the controller's
+// worker goroutines.
+func (r *Reconciler) handleDelete(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(cluster, naming.FinalizerPostgresCluster) {
+		// Nothing left for the operator to do; the apiserver will finish
+		// removing the object once any remaining owned dependents are gone.
+		return reconcile.Result{}, nil
+	}
+
+	policy := deletionPolicy(cluster)
+	foreground := isForegroundDeletion(cluster)
+
+	instances, err := r.instanceStatefulSets(ctx, cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	replicas, primary := splitInstancesByRole(instances)
+
+	// A foreground delete leaves the "foregroundDeletion" finalizer on the
+	// CR while Kubernetes waits for every owned dependent to disappear, so
+	// there is no rush: quiesce the primary first to stop Patroni promoting
+	// a replica mid-teardown, then requeue on a gentle backoff between each
+	// step below rather than polling in a tight loop.
+	requeue := reconcile.Result{Requeue: true}
+	if foreground {
+		requeue = reconcile.Result{RequeueAfter: foregroundDeleteBackoff}
+	}
+
+	// Take the final pgBackRest backup, if requested, before anything else
+	// is torn down: the primary needs to still be running to back it up.
+	if done, err := r.reconcileFinalBackup(ctx, cluster, primary); err != nil {
+		return reconcile.Result{}, err
+	} else if !done {
+		return requeue, nil
+	}
+
+	if foreground {
+		if primary != nil {
+			if err := r.quiescePrimary(ctx, cluster, primary); err != nil {
+				r.Recorder.Eventf(cluster, v1.EventTypeWarning, "QuiesceFailed",
+					"Could not pause Patroni before deleting: %v", err)
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	// Stop replicas one at a time, before the primary, so that failovers do
+	// not race the deletion.
+	if len(replicas) > 0 {
+		if err := r.Client.Delete(ctx, &replicas[0]); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+		if foreground {
+			if err := r.waitForPodGone(ctx, cluster, &replicas[0]); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return requeue, nil
+	}
+
+	if primary != nil {
+		if err := r.Client.Delete(ctx, primary); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+		return requeue, nil
+	}
+
+	// Wait for the instance Pods to finish terminating before touching the
+	// Patroni DCS; Patroni may otherwise recreate its Endpoints out from
+	// under us. - https://issue.k8s.io/99407
+	pods := &v1.PodList{}
+	selector, err := instanceSelector(cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.Client.List(ctx, pods,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(pods.Items) > 0 {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+
+	if !policy.RetainPatroniDCS {
+		if err := r.deletePatroniDCS(ctx, cluster); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err := r.retainPatroniDCS(ctx, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.deletePVCs(ctx, cluster, policy); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.deleteSecrets(ctx, cluster, policy); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	before := cluster.DeepCopy()
+	controllerutil.RemoveFinalizer(cluster, naming.FinalizerPostgresCluster)
+	if err := r.Client.Patch(ctx, cluster, client.MergeFrom(before), r.Owner); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// deletePatroniDCS removes the Endpoints and ConfigMaps that make up
+// Patroni's distributed configuration store for cluster.
+func (r *Reconciler) deletePatroniDCS(ctx context.Context, cluster *v1alpha1.PostgresCluster) error {
+	selector, err := patroniDCSSelector(cluster)
+	if err != nil {
+		return err
+	}
+
+	endpoints := &v1.EndpointsList{}
+	if err := r.Client.List(ctx, endpoints,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+	for i := range endpoints.Items {
+		if err := r.Client.Delete(ctx, &endpoints.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	configMaps := &v1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+	for i := range configMaps.Items {
+		if err := r.Client.Delete(ctx, &configMaps.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retainPatroniDCS clears cluster's owner reference from the Patroni DCS
+// objects instead of deleting them, so garbage collection leaves them be.
+func (r *Reconciler) retainPatroniDCS(ctx context.Context, cluster *v1alpha1.PostgresCluster) error {
+	selector, err := patroniDCSSelector(cluster)
+	if err != nil {
+		return err
+	}
+
+	endpoints := &v1.EndpointsList{}
+	if err := r.Client.List(ctx, endpoints,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+	for i := range endpoints.Items {
+		if err := r.retainOwnedObject(ctx, cluster, &endpoints.Items[i]); err != nil {
+			return err
+		}
+	}
+
+	configMaps := &v1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+	for i := range configMaps.Items {
+		if err := r.retainOwnedObject(ctx, cluster, &configMaps.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletePVCs removes the PersistentVolumeClaims backing cluster's instances,
+// unless policy asks to retain them, in which case their owner references
+// to cluster are cleared instead.
+func (r *Reconciler) deletePVCs(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, policy v1alpha1.PostgresClusterDeletionPolicy,
+) error {
+	selector, err := instanceSelector(cluster)
+	if err != nil {
+		return err
+	}
+
+	pvcs := &v1.PersistentVolumeClaimList{}
+	if err := r.Client.List(ctx, pvcs,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+
+	for i := range pvcs.Items {
+		if policy.RetainPVCs {
+			if err := r.retainOwnedObject(ctx, cluster, &pvcs.Items[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.Client.Delete(ctx, &pvcs.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteSecrets removes the Secrets the operator generated for cluster --
+// backup credentials, TLS certificates, the replication user's password --
+// unless policy asks to retain them.
+func (r *Reconciler) deleteSecrets(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster, policy v1alpha1.PostgresClusterDeletionPolicy,
+) error {
+	selector, err := instanceSelector(cluster)
+	if err != nil {
+		return err
+	}
+
+	secrets := &v1.SecretList{}
+	if err := r.Client.List(ctx, secrets,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return err
+	}
+
+	for i := range secrets.Items {
+		if policy.RetainSecrets {
+			if err := r.retainOwnedObject(ctx, cluster, &secrets.Items[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.Client.Delete(ctx, &secrets.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -97,39 +97,129 @@ func TestReconcilerHandleDelete(t *testing.T) {
 		}},
 	}))
 
-	reconciler := Reconciler{
-		Client:   cc,
-		Owner:    client.FieldOwner(t.Name()),
-		Recorder: new(record.FakeRecorder),
-		Tracer:   otel.Tracer(t.Name()),
-	}
-
-	reconciler.PodExec, err = newPodExecutor(config)
+	podExec, err := newPodExecutor(config)
 	assert.NilError(t, err)
 
-	mustReconcile := func(t *testing.T, cluster *v1alpha1.PostgresCluster) reconcile.Result {
-		t.Helper()
-		key := client.ObjectKeyFromObject(cluster)
-		request := reconcile.Request{NamespacedName: key}
-		result, err := reconciler.Reconcile(ctx, request)
-		assert.NilError(t, err, "%+v", err)
-		return result
-	}
-
-	for _, test := range []struct {
+	type deleteTestCase struct {
 		name         string
 		beforeCreate func(*testing.T, *v1alpha1.PostgresCluster)
 		beforeDelete func(*testing.T, *v1alpha1.PostgresCluster)
+		afterDelete  func(*testing.T, *v1alpha1.PostgresCluster)
 		propagation  metav1.DeletionPropagation
 
 		waitForRunningInstances int32
-	}{
+	}
+
+	cases := []deleteTestCase{
 		// Normal delete of a healthly cluster.
 		{
 			name: "Background", propagation: metav1.DeletePropagationBackground,
 			waitForRunningInstances: 2,
 		},
-		// TODO(cbandy): metav1.DeletePropagationForeground
+		// Foreground delete: the CR must not disappear until every owned
+		// StatefulSet/Pod it cascades to is actually gone.
+		{
+			name: "Foreground", propagation: metav1.DeletePropagationForeground,
+			waitForRunningInstances: 2,
+		},
+
+		// retainPVCs leaves the data volumes behind for a later re-adopt.
+		{
+			name: "RetainPVCs", propagation: metav1.DeletePropagationBackground,
+			waitForRunningInstances: 2,
+
+			beforeCreate: func(_ *testing.T, cluster *v1alpha1.PostgresCluster) {
+				cluster.Spec.DeletionPolicy = &v1alpha1.PostgresClusterDeletionPolicy{
+					RetainPVCs: true,
+				}
+			},
+			afterDelete: func(t *testing.T, cluster *v1alpha1.PostgresCluster) {
+				list := v1.PersistentVolumeClaimList{}
+				selector, err := labels.Parse(
+					"postgres-operator.crunchydata.com/cluster=" + cluster.Name)
+				assert.NilError(t, err)
+				assert.NilError(t, cc.List(ctx, &list,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector}))
+				assert.Assert(t, len(list.Items) > 0,
+					"expected PVCs to remain when retainPVCs is set")
+			},
+		},
+
+		// retainSecrets leaves the generated credentials/certificates behind.
+		{
+			name: "RetainSecrets", propagation: metav1.DeletePropagationBackground,
+			waitForRunningInstances: 2,
+
+			beforeCreate: func(_ *testing.T, cluster *v1alpha1.PostgresCluster) {
+				cluster.Spec.DeletionPolicy = &v1alpha1.PostgresClusterDeletionPolicy{
+					RetainSecrets: true,
+				}
+			},
+			afterDelete: func(t *testing.T, cluster *v1alpha1.PostgresCluster) {
+				list := v1.SecretList{}
+				selector, err := labels.Parse(
+					"postgres-operator.crunchydata.com/cluster=" + cluster.Name)
+				assert.NilError(t, err)
+				assert.NilError(t, cc.List(ctx, &list,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector}))
+				assert.Assert(t, len(list.Items) > 0,
+					"expected Secrets to remain when retainSecrets is set")
+			},
+		},
+
+		// retainPatroniDCS leaves Patroni's Endpoints/ConfigMaps behind so a
+		// re-created cluster can rejoin the same Patroni history.
+		{
+			name: "RetainPatroniDCS", propagation: metav1.DeletePropagationBackground,
+			waitForRunningInstances: 2,
+
+			beforeCreate: func(_ *testing.T, cluster *v1alpha1.PostgresCluster) {
+				cluster.Spec.DeletionPolicy = &v1alpha1.PostgresClusterDeletionPolicy{
+					RetainPatroniDCS: true,
+				}
+			},
+			afterDelete: func(t *testing.T, cluster *v1alpha1.PostgresCluster) {
+				list := v1.EndpointsList{}
+				selector, err := labels.Parse(strings.Join([]string{
+					"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+					"postgres-operator.crunchydata.com/patroni",
+				}, ","))
+				assert.NilError(t, err)
+				assert.NilError(t, cc.List(ctx, &list,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector}))
+				assert.Assert(t, len(list.Items) > 0,
+					"expected Patroni DCS objects to remain when retainPatroniDCS is set")
+			},
+		},
+
+		// preserveOnDelete is shorthand for retaining every kind, including
+		// the Patroni DCS.
+		{
+			name: "PreserveOnDelete", propagation: metav1.DeletePropagationBackground,
+			waitForRunningInstances: 2,
+
+			beforeCreate: func(_ *testing.T, cluster *v1alpha1.PostgresCluster) {
+				cluster.Spec.DeletionPolicy = &v1alpha1.PostgresClusterDeletionPolicy{
+					PreserveOnDelete: true,
+				}
+			},
+			afterDelete: func(t *testing.T, cluster *v1alpha1.PostgresCluster) {
+				list := v1.EndpointsList{}
+				selector, err := labels.Parse(strings.Join([]string{
+					"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+					"postgres-operator.crunchydata.com/patroni",
+				}, ","))
+				assert.NilError(t, err)
+				assert.NilError(t, cc.List(ctx, &list,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector}))
+				assert.Assert(t, len(list.Items) > 0,
+					"expected Patroni DCS objects to remain when preserveOnDelete is set")
+			},
+		},
 
 		// Normal delete of a healthy cluster after a failover.
 		{
@@ -160,7 +250,7 @@ func TestReconcilerHandleDelete(t *testing.T) {
 				assert.Assert(t, replica != nil, "expected to find a replica in %+v", list.Items)
 
 				assert.NilError(t, patroni.Executor(func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
-					return reconciler.PodExec(replica.Namespace, replica.Name, "database", stdin, stdout, stderr, command...)
+					return podExec(replica.Namespace, replica.Name, "database", stdin, stdout, stderr, command...)
 				}).ChangePrimary(ctx, primary.Name, replica.Name))
 			},
 		},
@@ -174,17 +264,333 @@ func TestReconcilerHandleDelete(t *testing.T) {
 				cluster.Spec.Image = "example.com/does-not-exist"
 			},
 		},
-	} {
-		t.Run(test.name, func(t *testing.T) {
+	}
+
+	// Run every case above against both DeletionModes so that retention
+	// policy, the final backup, and the Patroni DCS/CR teardown are all
+	// covered the same way regardless of who drives the cascade.
+	for _, mode := range []DeletionMode{DeletionModeFinalizer, DeletionModeOwnerReferences} {
+		mode := mode
+		t.Run(string(mode), func(t *testing.T) {
+			reconciler := Reconciler{
+				Client:       cc,
+				Owner:        client.FieldOwner(t.Name()),
+				Recorder:     new(record.FakeRecorder),
+				Tracer:       otel.Tracer(t.Name()),
+				DeletionMode: mode,
+				PodExec:      podExec,
+			}
+
+			mustReconcile := func(t *testing.T, cluster *v1alpha1.PostgresCluster) reconcile.Result {
+				t.Helper()
+				key := client.ObjectKeyFromObject(cluster)
+				request := reconcile.Request{NamespacedName: key}
+				result, err := reconciler.Reconcile(ctx, request)
+				assert.NilError(t, err, "%+v", err)
+				return result
+			}
+
+			for _, test := range cases {
+				test := test
+				t.Run(test.name, func(t *testing.T) {
+					g := gomega.NewWithT(t)
+
+					cluster := &v1alpha1.PostgresCluster{}
+					assert.NilError(t, yaml.Unmarshal([]byte(`{
+						spec: {
+							postgresVersion: 12,
+							instances: [
+								{
+									replicas: 2,
+									volumeClaimSpec: {
+										accessModes: [ReadWriteOnce],
+										resources: { requests: { storage: 1Gi } },
+									},
+								},
+							],
+						},
+					}`), cluster))
+
+					cluster.Namespace = ns.Name
+					cluster.Name = strings.ToLower(string(mode)) + "-" + strings.ToLower(test.name)
+					cluster.Spec.Image = CrunchyPostgresHAImage
+
+					if test.beforeCreate != nil {
+						test.beforeCreate(t, cluster)
+					}
+
+					assert.NilError(t, cc.Create(ctx, cluster))
+
+					t.Cleanup(func() {
+						// Remove finalizers, if any, so the namespace can terminate.
+						assert.Check(t, client.IgnoreNotFound(
+							cc.Patch(ctx, cluster, client.RawPatch(
+								client.Merge.Type(), []byte(`{"metadata":{"finalizers":[]}}`)))))
+					})
+
+					// Start cluster.
+					mustReconcile(t, cluster)
+
+					assert.NilError(t,
+						cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
+					if mode == DeletionModeOwnerReferences {
+						g.Expect(cluster.Finalizers).NotTo(
+							gomega.ContainElement("postgres-operator.crunchydata.com/finalizer"),
+							"owner-references mode should not attach the operator's full teardown finalizer")
+						g.Expect(cluster.Finalizers).To(
+							gomega.ContainElement("postgres-operator.crunchydata.com/quiesce-finalizer"),
+							"owner-references mode should still hold the object open long enough to quiesce it")
+					} else {
+						g.Expect(cluster.Finalizers).To(
+							gomega.ContainElement("postgres-operator.crunchydata.com/finalizer"),
+							"cluster should immediately have a finalizer")
+					}
+
+					// Continue until instances are healthy.
+					g.Eventually(func() (instances []appsv1.StatefulSet) {
+						mustReconcile(t, cluster)
+
+						list := appsv1.StatefulSetList{}
+						selector, err := labels.Parse(strings.Join([]string{
+							"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+							"postgres-operator.crunchydata.com/instance",
+						}, ","))
+						assert.NilError(t, err)
+						assert.NilError(t, cc.List(ctx, &list,
+							client.InNamespace(cluster.Namespace),
+							client.MatchingLabelsSelector{Selector: selector}))
+						return list.Items
+					},
+						"60s", // timeout
+						"1s",  // interval
+					).Should(gomega.WithTransform(func(instances []appsv1.StatefulSet) int {
+						ready := 0
+						for _, sts := range instances {
+							ready += int(sts.Status.ReadyReplicas)
+						}
+						return ready
+					}, gomega.BeNumerically(">=", test.waitForRunningInstances)))
+
+					if mode == DeletionModeOwnerReferences && test.waitForRunningInstances > 0 {
+						instances := appsv1.StatefulSetList{}
+						selector, err := labels.Parse(strings.Join([]string{
+							"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+							"postgres-operator.crunchydata.com/instance",
+						}, ","))
+						assert.NilError(t, err)
+						assert.NilError(t, cc.List(ctx, &instances,
+							client.InNamespace(cluster.Namespace),
+							client.MatchingLabelsSelector{Selector: selector}))
+						for i := range instances.Items {
+							g.Expect(instances.Items[i].OwnerReferences).To(gomega.ContainElement(
+								gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+									"UID":                gomega.Equal(cluster.UID),
+									"BlockOwnerDeletion": gomega.HaveValue(gomega.BeTrue()),
+								}),
+							), "expected the instance StatefulSet to block cluster deletion")
+						}
+					}
+
+					if test.beforeDelete != nil {
+						test.beforeDelete(t, cluster)
+					}
+
+					switch test.propagation {
+					case metav1.DeletePropagationBackground:
+						// Background deletion is the default for custom resources.
+						// - https://issue.k8s.io/81628
+						assert.NilError(t, cc.Delete(ctx, cluster))
+					default:
+						assert.NilError(t, cc.Delete(ctx, cluster,
+							client.PropagationPolicy(test.propagation)))
+					}
+
+					// Stop cluster.
+					result := mustReconcile(t, cluster)
+
+					// Both modes stage their own teardown one step at a time --
+					// DeletionModeFinalizer via handleDelete, DeletionModeOwnerReferences
+					// via handleDeleteOwnerReferences and its own naming.FinalizerQuiesce
+					// -- so replicas are guaranteed to stop before the primary and the
+					// Patroni DCS is guaranteed to still exist in between, under either
+					// mode. Kubernetes garbage collection only takes over the remaining
+					// child kinds once the CR itself is actually removed from etcd,
+					// after that staging completes.
+					if test.waitForRunningInstances > 0 {
+
+						// Replicas should stop first, leaving just the one primary.
+						g.Eventually(func() (instances []v1.Pod) {
+							if result.Requeue {
+								result = mustReconcile(t, cluster)
+							}
+
+							list := v1.PodList{}
+							selector, err := labels.Parse(strings.Join([]string{
+								"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+								"postgres-operator.crunchydata.com/instance",
+							}, ","))
+							assert.NilError(t, err)
+							assert.NilError(t, cc.List(ctx, &list,
+								client.InNamespace(cluster.Namespace),
+								client.MatchingLabelsSelector{Selector: selector}))
+							return list.Items
+						},
+							"60s", // timeout
+							"1s",  // interval
+						).Should(gomega.ConsistOf(
+							gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+								"ObjectMeta": gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+									"Labels": gstruct.MatchKeys(gstruct.IgnoreExtras, gstruct.Keys{
+										// Patroni doesn't use "primary" to identify the primary.
+										"postgres-operator.crunchydata.com/role": gomega.Equal("master"),
+									}),
+								}),
+							}),
+						), "expected one instance")
+
+						// Patroni DCS objects should not be deleted yet.
+						{
+							list := v1.EndpointsList{}
+							selector, err := labels.Parse(strings.Join([]string{
+								"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+								"postgres-operator.crunchydata.com/patroni",
+							}, ","))
+							assert.NilError(t, err)
+							assert.NilError(t, cc.List(ctx, &list,
+								client.InNamespace(cluster.Namespace),
+								client.MatchingLabelsSelector{Selector: selector}))
+
+							assert.Assert(t, len(list.Items) >= 2, // config + leader
+								"expected Patroni DCS objects to remain, there are %v",
+								len(list.Items))
+
+							// Endpoints are deleted differently than other resources, and
+							// Patroni might have recreated them to stay alive. Check that
+							// they are all from before the cluster delete operation.
+							// - https://issue.k8s.io/99407
+							assert.NilError(t,
+								cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
+							g.Expect(list.Items).To(gstruct.MatchElements(
+								func(interface{}) string { return "each" },
+								gstruct.AllowDuplicates,
+								gstruct.Elements{
+									"each": gomega.WithTransform(
+										func(ep v1.Endpoints) time.Time {
+											return ep.CreationTimestamp.Time
+										},
+										gomega.BeTemporally("<", cluster.DeletionTimestamp.Time),
+									),
+								},
+							))
+						}
+					}
+
+					// Continue until cluster is gone.
+					g.Eventually(func() error {
+						mustReconcile(t, cluster)
+
+						return cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)
+					},
+						"60s", // timeout
+						"1s",  // interval
+					).Should(gomega.SatisfyAll(
+						gomega.HaveOccurred(),
+						gomega.WithTransform(apierrors.IsNotFound, gomega.BeTrue()),
+					))
+
+					if test.afterDelete != nil {
+						test.afterDelete(t, cluster)
+					} else {
+						g.Eventually(func() []v1.Endpoints {
+							list := v1.EndpointsList{}
+							selector, err := labels.Parse(strings.Join([]string{
+								"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+								"postgres-operator.crunchydata.com/patroni",
+							}, ","))
+							assert.NilError(t, err)
+							assert.NilError(t, cc.List(ctx, &list,
+								client.InNamespace(cluster.Namespace),
+								client.MatchingLabelsSelector{Selector: selector}))
+							return list.Items
+						},
+							"20s", // timeout
+							"1s",  // interval
+						).Should(gomega.BeEmpty(), "Patroni DCS objects should be gone")
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestReconcilerHandleDeleteFinalBackup(t *testing.T) {
+	if !strings.EqualFold(os.Getenv("USE_EXISTING_CLUSTER"), "true") {
+		t.Skip("requires a running garbage collection controller")
+	}
+
+	ctx := context.Background()
+	env := &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+		},
+	}
+
+	options := client.Options{}
+	options.Scheme = runtime.NewScheme()
+	assert.NilError(t, scheme.AddToScheme(options.Scheme))
+	assert.NilError(t, v1alpha1.AddToScheme(options.Scheme))
+
+	config, err := env.Start()
+	assert.NilError(t, err)
+	t.Cleanup(func() { assert.Check(t, env.Stop()) })
+
+	cc, err := client.New(config, options)
+	assert.NilError(t, err)
+
+	ns := &v1.Namespace{}
+	ns.GenerateName = "postgres-operator-test-"
+	ns.Labels = labels.Set{"postgres-operator-test": t.Name()}
+	assert.NilError(t, cc.Create(ctx, ns))
+	t.Cleanup(func() { assert.Check(t, cc.Delete(ctx, ns)) })
+
+	podExec, err := newPodExecutor(config)
+	assert.NilError(t, err)
+
+	// Run under both DeletionModes, with ordinary Background propagation (the
+	// default for custom resources), to prove the final backup is taken and
+	// awaited even though DeletionModeOwnerReferences no longer has a
+	// controller-owned finalizer driving its whole teardown.
+	for _, mode := range []DeletionMode{DeletionModeFinalizer, DeletionModeOwnerReferences} {
+		mode := mode
+		t.Run(string(mode), func(t *testing.T) {
+			reconciler := Reconciler{
+				Client:       cc,
+				Owner:        client.FieldOwner(t.Name()),
+				Recorder:     new(record.FakeRecorder),
+				Tracer:       otel.Tracer(t.Name()),
+				DeletionMode: mode,
+				PodExec:      podExec,
+			}
+
+			mustReconcile := func(t *testing.T, cluster *v1alpha1.PostgresCluster) reconcile.Result {
+				t.Helper()
+				key := client.ObjectKeyFromObject(cluster)
+				request := reconcile.Request{NamespacedName: key}
+				result, err := reconciler.Reconcile(ctx, request)
+				assert.NilError(t, err, "%+v", err)
+				return result
+			}
+
 			g := gomega.NewWithT(t)
 
 			cluster := &v1alpha1.PostgresCluster{}
 			assert.NilError(t, yaml.Unmarshal([]byte(`{
 				spec: {
 					postgresVersion: 12,
+					backupBeforeDelete: Required,
 					instances: [
 						{
-							replicas: 2,
+							replicas: 1,
 							volumeClaimSpec: {
 								accessModes: [ReadWriteOnce],
 								resources: { requests: { storage: 1Gi } },
@@ -195,32 +601,18 @@ func TestReconcilerHandleDelete(t *testing.T) {
 			}`), cluster))
 
 			cluster.Namespace = ns.Name
-			cluster.Name = strings.ToLower(test.name)
+			cluster.Name = strings.ToLower(string(mode)) + "-required-backup"
 			cluster.Spec.Image = CrunchyPostgresHAImage
 
-			if test.beforeCreate != nil {
-				test.beforeCreate(t, cluster)
-			}
-
 			assert.NilError(t, cc.Create(ctx, cluster))
-
 			t.Cleanup(func() {
-				// Remove finalizers, if any, so the namespace can terminate.
 				assert.Check(t, client.IgnoreNotFound(
 					cc.Patch(ctx, cluster, client.RawPatch(
 						client.Merge.Type(), []byte(`{"metadata":{"finalizers":[]}}`)))))
 			})
 
-			// Start cluster.
 			mustReconcile(t, cluster)
 
-			assert.NilError(t,
-				cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
-			g.Expect(cluster.Finalizers).To(
-				gomega.ContainElement("postgres-operator.crunchydata.com/finalizer"),
-				"cluster should immediately have a finalizer")
-
-			// Continue until instances are healthy.
 			g.Eventually(func() (instances []appsv1.StatefulSet) {
 				mustReconcile(t, cluster)
 
@@ -234,132 +626,37 @@ func TestReconcilerHandleDelete(t *testing.T) {
 					client.InNamespace(cluster.Namespace),
 					client.MatchingLabelsSelector{Selector: selector}))
 				return list.Items
-			},
-				"60s", // timeout
-				"1s",  // interval
-			).Should(gomega.WithTransform(func(instances []appsv1.StatefulSet) int {
+			}, "60s", "1s").Should(gomega.WithTransform(func(instances []appsv1.StatefulSet) int {
 				ready := 0
 				for _, sts := range instances {
 					ready += int(sts.Status.ReadyReplicas)
 				}
 				return ready
-			}, gomega.BeNumerically(">=", test.waitForRunningInstances)))
-
-			if test.beforeDelete != nil {
-				test.beforeDelete(t, cluster)
-			}
-
-			switch test.propagation {
-			case metav1.DeletePropagationBackground:
-				// Background deletion is the default for custom resources.
-				// - https://issue.k8s.io/81628
-				assert.NilError(t, cc.Delete(ctx, cluster))
-			default:
-				assert.NilError(t, cc.Delete(ctx, cluster,
-					client.PropagationPolicy(test.propagation)))
-			}
-
-			// Stop cluster.
-			result := mustReconcile(t, cluster)
-
-			// If things started running, then they should stop in a certain order.
-			if test.waitForRunningInstances > 0 {
-
-				// Replicas should stop first, leaving just the one primary.
-				g.Eventually(func() (instances []v1.Pod) {
-					if result.Requeue {
-						result = mustReconcile(t, cluster)
-					}
+			}, gomega.BeNumerically(">=", 1)))
 
-					list := v1.PodList{}
-					selector, err := labels.Parse(strings.Join([]string{
-						"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
-						"postgres-operator.crunchydata.com/instance",
-					}, ","))
-					assert.NilError(t, err)
-					assert.NilError(t, cc.List(ctx, &list,
-						client.InNamespace(cluster.Namespace),
-						client.MatchingLabelsSelector{Selector: selector}))
-					return list.Items
-				},
-					"60s", // timeout
-					"1s",  // interval
-				).Should(gomega.ConsistOf(
-					gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
-						"ObjectMeta": gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
-							"Labels": gstruct.MatchKeys(gstruct.IgnoreExtras, gstruct.Keys{
-								// Patroni doesn't use "primary" to identify the primary.
-								"postgres-operator.crunchydata.com/role": gomega.Equal("master"),
-							}),
-						}),
-					}),
-				), "expected one instance")
+			// Background deletion is the default for custom resources.
+			// - https://issue.k8s.io/81628
+			assert.NilError(t, cc.Delete(ctx, cluster))
 
-				// Patroni DCS objects should not be deleted yet.
-				{
-					list := v1.EndpointsList{}
-					selector, err := labels.Parse(strings.Join([]string{
-						"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
-						"postgres-operator.crunchydata.com/patroni",
-					}, ","))
-					assert.NilError(t, err)
-					assert.NilError(t, cc.List(ctx, &list,
-						client.InNamespace(cluster.Namespace),
-						client.MatchingLabelsSelector{Selector: selector}))
-
-					assert.Assert(t, len(list.Items) >= 2, // config + leader
-						"expected Patroni DCS objects to remain, there are %v",
-						len(list.Items))
-
-					// Endpoints are deleted differently than other resources, and
-					// Patroni might have recreated them to stay alive. Check that
-					// they are all from before the cluster delete operation.
-					// - https://issue.k8s.io/99407
-					assert.NilError(t,
-						cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
-					g.Expect(list.Items).To(gstruct.MatchElements(
-						func(interface{}) string { return "each" },
-						gstruct.AllowDuplicates,
-						gstruct.Elements{
-							"each": gomega.WithTransform(
-								func(ep v1.Endpoints) time.Time {
-									return ep.CreationTimestamp.Time
-								},
-								gomega.BeTemporally("<", cluster.DeletionTimestamp.Time),
-							),
-						},
-					))
-				}
-			}
+			// The backup is reported in-progress, and the cluster must still exist
+			// while the reconciler waits for it, before the rest of the teardown
+			// (and, eventually, the CR itself) proceeds.
+			mustReconcile(t, cluster)
+			assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
+			g.Expect(cluster.Status.Conditions).To(gomega.ContainElement(
+				gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+					"Type":   gomega.Equal("FinalBackup"),
+					"Reason": gomega.Equal("InProgress"),
+				}),
+			))
 
-			// Continue until cluster is gone.
 			g.Eventually(func() error {
 				mustReconcile(t, cluster)
-
 				return cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)
-			},
-				"60s", // timeout
-				"1s",  // interval
-			).Should(gomega.SatisfyAll(
+			}, "60s", "1s").Should(gomega.SatisfyAll(
 				gomega.HaveOccurred(),
 				gomega.WithTransform(apierrors.IsNotFound, gomega.BeTrue()),
 			))
-
-			g.Eventually(func() []v1.Endpoints {
-				list := v1.EndpointsList{}
-				selector, err := labels.Parse(strings.Join([]string{
-					"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
-					"postgres-operator.crunchydata.com/patroni",
-				}, ","))
-				assert.NilError(t, err)
-				assert.NilError(t, cc.List(ctx, &list,
-					client.InNamespace(cluster.Namespace),
-					client.MatchingLabelsSelector{Selector: selector}))
-				return list.Items
-			},
-				"20s", // timeout
-				"1s",  // interval
-			).Should(gomega.BeEmpty(), "Patroni DCS objects should be gone")
 		})
 	}
 }
@@ -504,3 +801,128 @@ func TestReconcilerHandleDeleteNamespace(t *testing.T) {
 		return apierrors.IsNotFound(err), client.IgnoreNotFound(err)
 	}), "expected namespace to be deleted")
 }
+
+// TestReconcilerPaused covers the postgres-operator.crunchydata.com/paused
+// annotation: a paused PostgresCluster must not grow any StatefulSets until
+// the annotation is removed.
+func TestReconcilerPaused(t *testing.T) {
+	if !strings.EqualFold(os.Getenv("USE_EXISTING_CLUSTER"), "true") {
+		t.Skip("requires a running garbage collection controller")
+	}
+
+	ctx := context.Background()
+	env := &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+		},
+	}
+
+	options := client.Options{}
+	options.Scheme = runtime.NewScheme()
+	assert.NilError(t, scheme.AddToScheme(options.Scheme))
+	assert.NilError(t, v1alpha1.AddToScheme(options.Scheme))
+
+	config, err := env.Start()
+	assert.NilError(t, err)
+	t.Cleanup(func() { assert.Check(t, env.Stop()) })
+
+	cc, err := client.New(config, options)
+	assert.NilError(t, err)
+
+	ns := &v1.Namespace{}
+	ns.GenerateName = "postgres-operator-test-"
+	ns.Labels = labels.Set{"postgres-operator-test": t.Name()}
+	assert.NilError(t, cc.Create(ctx, ns))
+	t.Cleanup(func() { assert.Check(t, client.IgnoreNotFound(cc.Delete(ctx, ns))) })
+
+	var mm struct {
+		manager.Manager
+		Context context.Context
+		Error   chan error
+		Stop    context.CancelFunc
+	}
+
+	mm.Context, mm.Stop = context.WithCancel(context.Background())
+	mm.Error = make(chan error, 1)
+	mm.Manager, err = manager.New(config, manager.Options{
+		Namespace: ns.Name,
+		Scheme:    options.Scheme,
+
+		HealthProbeBindAddress: "0", // disable
+		MetricsBindAddress:     "0", // disable
+	})
+	assert.NilError(t, err)
+
+	reconciler := Reconciler{
+		Client:   mm.GetClient(),
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+		Tracer:   otel.Tracer(t.Name()),
+	}
+	assert.NilError(t, reconciler.SetupWithManager(mm.Manager))
+
+	go func() { mm.Error <- mm.Start(mm.Context) }()
+	t.Cleanup(func() { mm.Stop(); assert.Check(t, <-mm.Error) })
+
+	cluster := &v1alpha1.PostgresCluster{}
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		metadata: {
+			annotations: { postgres-operator.crunchydata.com/paused: "true" },
+		},
+		spec: {
+			postgresVersion: 12,
+			instances: [
+				{
+					replicas: 1,
+					volumeClaimSpec: {
+						accessModes: [ReadWriteOnce],
+						resources: { requests: { storage: 1Gi } },
+					},
+				},
+			],
+		},
+	}`), cluster))
+
+	cluster.Namespace = ns.Name
+	cluster.Name = "paused"
+	cluster.Spec.Image = CrunchyPostgresHAImage
+
+	assert.NilError(t, cc.Create(ctx, cluster))
+	t.Cleanup(func() { assert.Check(t, client.IgnoreNotFound(cc.Delete(ctx, cluster))) })
+
+	g := gomega.NewWithT(t)
+	selector, err := labels.Parse(strings.Join([]string{
+		"postgres-operator.crunchydata.com/cluster=" + cluster.Name,
+		"postgres-operator.crunchydata.com/instance",
+	}, ","))
+	assert.NilError(t, err)
+
+	instances := func() []appsv1.StatefulSet {
+		list := appsv1.StatefulSetList{}
+		assert.NilError(t, cc.List(ctx, &list,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector}))
+		return list.Items
+	}
+
+	// Give the controller a few reconciles worth of time to (incorrectly)
+	// create a StatefulSet; it should not.
+	g.Consistently(instances, "5s", "1s").Should(gomega.BeEmpty(),
+		"a paused cluster should not grow any StatefulSets")
+
+	assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(cluster), cluster))
+	g.Expect(cluster.Status.Conditions).To(gomega.ContainElement(
+		gstruct.MatchFields(gstruct.IgnoreExtras, gstruct.Fields{
+			"Type":   gomega.Equal("Paused"),
+			"Status": gomega.Equal(metav1.ConditionTrue),
+		}),
+	))
+
+	// Remove the annotation; the controller should now create instances.
+	before := cluster.DeepCopy()
+	delete(cluster.Annotations, "postgres-operator.crunchydata.com/paused")
+	assert.NilError(t, cc.Patch(ctx, cluster, client.MergeFrom(before)))
+
+	g.Eventually(instances, "60s", "1s").ShouldNot(gomega.BeEmpty(),
+		"expected the cluster to resume once unpaused")
+}
@@ -0,0 +1,294 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"os"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1alpha1"
+)
+
+// DeletionMode selects how the operator ties the lifecycle of a
+// PostgresCluster's children to the lifecycle of the CR itself.
+type DeletionMode string
+
+const (
+	// DeletionModeFinalizer is the default: the operator attaches its own
+	// finalizer and drives the entire teardown itself. See handleDelete.
+	DeletionModeFinalizer DeletionMode = "finalizer"
+
+	// DeletionModeOwnerReferences leaves most teardown to Kubernetes garbage
+	// collection: every child object carries an OwnerReference back to the
+	// PostgresCluster, so kinds that deletionPolicy doesn't ask to retain are
+	// cleaned up once the CR itself is gone. The operator still attaches
+	// naming.FinalizerQuiesce of its own, just long enough to take the final
+	// pgBackRest backup, quiesce Patroni, and stop replicas before the
+	// primary -- see handleDeleteOwnerReferences for why that can't be left
+	// to garbage collection either.
+	DeletionModeOwnerReferences DeletionMode = "owner-references"
+)
+
+// deletionModeEnvVar is the environment variable operators use to select a
+// Reconciler's DeletionMode. It is read by DeletionModeFromEnv.
+const deletionModeEnvVar = "PGO_DELETION_MODE"
+
+// DeletionModeFromEnv returns the DeletionMode named by PGO_DELETION_MODE,
+// defaulting to DeletionModeFinalizer for back-compat with existing
+// deployments when the variable is unset or unrecognized.
+func DeletionModeFromEnv() DeletionMode {
+	switch DeletionMode(os.Getenv(deletionModeEnvVar)) {
+	case DeletionModeOwnerReferences:
+		return DeletionModeOwnerReferences
+	default:
+		return DeletionModeFinalizer
+	}
+}
+
+// reconcileOwnerReferences ensures cluster carries naming.FinalizerQuiesce,
+// then stamps every child object of cluster with an OwnerReference back to
+// it, so that Kubernetes garbage collection tears them down once cluster
+// itself is gone. Kinds that cluster's deletionPolicy asks to retain are
+// left without an owner reference so that garbage collection leaves them
+// alone.
+//
+// BlockOwnerDeletion on the instance StatefulSets only makes the apiserver
+// wait for them under metav1.DeletePropagationForeground, so it is not what
+// guarantees replicas-before-primary ordering or the pre-delete backup and
+// quiesce here -- naming.FinalizerQuiesce is. See handleDeleteOwnerReferences.
+func (r *Reconciler) reconcileOwnerReferences(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) error {
+	if err := r.reconcileQuiesceFinalizer(ctx, cluster); err != nil {
+		return err
+	}
+
+	policy := deletionPolicy(cluster)
+
+	blocking := metav1.OwnerReference{
+		APIVersion:         v1alpha1.GroupVersion.String(),
+		Kind:               "PostgresCluster",
+		Name:               cluster.Name,
+		UID:                cluster.UID,
+		BlockOwnerDeletion: pointer.Bool(true),
+		Controller:         pointer.Bool(true),
+	}
+	nonBlocking := blocking
+	nonBlocking.BlockOwnerDeletion = pointer.Bool(false)
+
+	instances, err := r.instanceStatefulSets(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	for i := range instances.Items {
+		if err := r.ownObject(ctx, &instances.Items[i], blocking); err != nil {
+			return err
+		}
+	}
+
+	instanceSel, err := instanceSelector(cluster)
+	if err != nil {
+		return err
+	}
+
+	services := &v1.ServiceList{}
+	if err := r.Client.List(ctx, services,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: instanceSel}); err != nil {
+		return err
+	}
+	for i := range services.Items {
+		if err := r.ownObject(ctx, &services.Items[i], nonBlocking); err != nil {
+			return err
+		}
+	}
+
+	if !policy.RetainPVCs {
+		pvcs := &v1.PersistentVolumeClaimList{}
+		if err := r.Client.List(ctx, pvcs,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: instanceSel}); err != nil {
+			return err
+		}
+		for i := range pvcs.Items {
+			if err := r.ownObject(ctx, &pvcs.Items[i], nonBlocking); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !policy.RetainSecrets {
+		secrets := &v1.SecretList{}
+		if err := r.Client.List(ctx, secrets,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: instanceSel}); err != nil {
+			return err
+		}
+		for i := range secrets.Items {
+			if err := r.ownObject(ctx, &secrets.Items[i], nonBlocking); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !policy.RetainPatroniDCS {
+		dcsSel, err := patroniDCSSelector(cluster)
+		if err != nil {
+			return err
+		}
+
+		endpoints := &v1.EndpointsList{}
+		if err := r.Client.List(ctx, endpoints,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: dcsSel}); err != nil {
+			return err
+		}
+		for i := range endpoints.Items {
+			if err := r.ownObject(ctx, &endpoints.Items[i], nonBlocking); err != nil {
+				return err
+			}
+		}
+
+		configMaps := &v1.ConfigMapList{}
+		if err := r.Client.List(ctx, configMaps,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: dcsSel}); err != nil {
+			return err
+		}
+		for i := range configMaps.Items {
+			if err := r.ownObject(ctx, &configMaps.Items[i], nonBlocking); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// reconcileQuiesceFinalizer ensures that cluster carries
+// naming.FinalizerQuiesce, so that handleDeleteOwnerReferences runs before
+// the object is removed from etcd no matter which propagation policy a
+// caller deletes it with.
+func (r *Reconciler) reconcileQuiesceFinalizer(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) error {
+	if controllerutil.ContainsFinalizer(cluster, naming.FinalizerQuiesce) {
+		return nil
+	}
+
+	before := cluster.DeepCopy()
+	controllerutil.AddFinalizer(cluster, naming.FinalizerQuiesce)
+	return r.Client.Patch(ctx, cluster, client.MergeFrom(before), r.Owner)
+}
+
+// ownObject patches obj to carry owner if it does not already have an
+// OwnerReference pointing at the same UID.
+func (r *Reconciler) ownObject(ctx context.Context, obj client.Object, owner metav1.OwnerReference) error {
+	if hasOwnerReference(obj.GetOwnerReferences(), owner.UID) {
+		return nil
+	}
+	before := obj.DeepCopyObject().(client.Object)
+	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), owner))
+	return r.Client.Patch(ctx, obj, client.MergeFrom(before), r.Owner)
+}
+
+func hasOwnerReference(owners []metav1.OwnerReference, uid types.UID) bool {
+	for _, owner := range owners {
+		if owner.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDeleteOwnerReferences runs the operator's half of an
+// owner-references mode deletion: the final pgBackRest backup cluster's spec
+// requires, quiescing Patroni on the primary, and stopping replicas before
+// the primary -- the same ordering DeletionModeFinalizer's handleDelete
+// enforces, except that Patroni is quiesced here under every propagation
+// policy, where handleDelete quiesces it only under
+// metav1.DeletePropagationForeground. These cannot be left to Kubernetes garbage collection the way the
+// rest of teardown is: BlockOwnerDeletion on an owned object only makes the
+// apiserver wait under metav1.DeletePropagationForeground, and Background is
+// the default for custom resources, so without a finalizer of its own the
+// CR would simply be removed from etcd on an ordinary delete before any of
+// this ever ran. naming.FinalizerQuiesce exists to hold the object open for
+// exactly as long as it takes to do this and nothing more; once it is
+// removed, the apiserver finishes deleting cluster and Kubernetes garbage
+// collection cascades through the remaining child objects via the
+// OwnerReferences reconcileOwnerReferences already stamped on them.
+func (r *Reconciler) handleDeleteOwnerReferences(
+	ctx context.Context, cluster *v1alpha1.PostgresCluster,
+) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(cluster, naming.FinalizerQuiesce) {
+		// Nothing left for the operator to do; Kubernetes garbage collection
+		// will finish removing the owned dependents on its own.
+		return reconcile.Result{}, nil
+	}
+
+	instances, err := r.instanceStatefulSets(ctx, cluster)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	replicas, primary := splitInstancesByRole(instances)
+
+	if done, err := r.reconcileFinalBackup(ctx, cluster, primary); err != nil {
+		return reconcile.Result{}, err
+	} else if !done {
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if primary != nil {
+		if err := r.quiescePrimary(ctx, cluster, primary); err != nil {
+			r.Recorder.Eventf(cluster, v1.EventTypeWarning, "QuiesceFailed",
+				"Could not pause Patroni before deleting: %v", err)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Stop replicas one at a time, before the primary, so that failovers do
+	// not race the deletion -- garbage collection would otherwise cascade
+	// through every owned StatefulSet with no ordering of its own.
+	if len(replicas) > 0 {
+		if err := r.Client.Delete(ctx, &replicas[0]); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	if primary != nil {
+		if err := r.Client.Delete(ctx, primary); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	before := cluster.DeepCopy()
+	controllerutil.RemoveFinalizer(cluster, naming.FinalizerQuiesce)
+	if err := r.Client.Patch(ctx, cluster, client.MergeFrom(before), r.Owner); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
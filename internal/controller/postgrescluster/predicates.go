@@ -0,0 +1,64 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+)
+
+// watchFilterEnvVar is the environment variable that WatchFilterValueFromEnv
+// reads to seed the Reconciler's watch-filter value. This package defines no
+// command-line flag for it.
+const watchFilterEnvVar = "PGO_WATCH_FILTER"
+
+// WatchFilterValueFromEnv returns the watch-filter value named by
+// PGO_WATCH_FILTER, or "" if it is unset, meaning every PostgresCluster is
+// watched regardless of its naming.LabelWatchFilter label.
+func WatchFilterValueFromEnv() string {
+	return os.Getenv(watchFilterEnvVar)
+}
+
+// hasMatchingFilterLabel reports whether obj should be watched given
+// filterValue: every object is watched when filterValue is empty, otherwise
+// only objects whose naming.LabelWatchFilter label equals filterValue are.
+func hasMatchingFilterLabel(obj client.Object, filterValue string) bool {
+	if filterValue == "" {
+		return true
+	}
+	return obj.GetLabels()[naming.LabelWatchFilter] == filterValue
+}
+
+// watchPredicates returns the controller-runtime predicate the Reconciler
+// installs on every watch in SetupWithManager. It borrows the shape of
+// cluster-api's predicates.ResourceNotPausedAndHasFilterLabel: it scopes the
+// controller to objects carrying a matching naming.LabelWatchFilter label
+// when r.WatchFilterValue is set, which allows several operator instances to
+// shard a namespace between them.
+//
+// Unlike cluster-api's predicate, a paused PostgresCluster is not filtered
+// out here -- Reconcile still runs for it so that it can record the Paused
+// condition -- pausing only short-circuits the work Reconcile does once
+// inside.
+func (r *Reconciler) watchPredicates() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return hasMatchingFilterLabel(obj, r.WatchFilterValue)
+	})
+}
@@ -0,0 +1,72 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package naming centralizes the labels, annotations, and finalizers that the
+// operator attaches to the objects it manages, so that every controller
+// agrees on their spelling.
+package naming
+
+const (
+	// labelPrefix is prepended to every label and annotation key the
+	// operator places on Kubernetes objects.
+	labelPrefix = "postgres-operator.crunchydata.com/"
+
+	// LabelCluster identifies the PostgresCluster that owns an object.
+	LabelCluster = labelPrefix + "cluster"
+
+	// LabelInstance identifies the instance set an object belongs to.
+	LabelInstance = labelPrefix + "instance"
+
+	// LabelRole identifies the PostgreSQL role of an instance. Patroni uses
+	// "master" rather than "primary" to identify the primary.
+	LabelRole = labelPrefix + "role"
+
+	// LabelPatroni identifies objects that make up Patroni's distributed
+	// configuration store (DCS).
+	LabelPatroni = labelPrefix + "patroni"
+
+	// RolePatroniLeader is the LabelRole value Patroni uses for the primary.
+	RolePatroniLeader = "master"
+
+	// RolePatroniReplica is the LabelRole value Patroni uses for replicas.
+	RolePatroniReplica = "replica"
+
+	// FinalizerPostgresCluster is attached to a PostgresCluster so that the
+	// operator can run its teardown before the object is removed from etcd.
+	FinalizerPostgresCluster = labelPrefix + "finalizer"
+
+	// ConditionFinalBackup is the status.conditions type the operator uses
+	// to report the progress of the pgBackRest backup taken before a
+	// cluster is deleted.
+	ConditionFinalBackup = "FinalBackup"
+
+	// AnnotationPaused, when set to "true" on a PostgresCluster, causes the
+	// controller to short-circuit reconciliation without removing the
+	// object from the cluster.
+	AnnotationPaused = labelPrefix + "paused"
+
+	// LabelWatchFilter, when the controller is started with a watch-filter
+	// value, scopes reconciliation to PostgresClusters carrying this label
+	// with a matching value.
+	LabelWatchFilter = labelPrefix + "watch-filter"
+
+	// FinalizerQuiesce is attached to a PostgresCluster in
+	// DeletionModeOwnerReferences so that the operator can take the final
+	// backup and quiesce Patroni before the object is removed from etcd,
+	// regardless of the propagation policy used to delete it --
+	// BlockOwnerDeletion on its owned objects only makes the apiserver wait
+	// under Foreground propagation, which the operator does not control.
+	FinalizerQuiesce = labelPrefix + "quiesce-finalizer"
+)
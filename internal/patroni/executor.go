@@ -0,0 +1,63 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package patroni
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Executor runs patronictl (or similar) commands against a single Postgres
+// instance Pod, typically by execing into its database container.
+type Executor func(
+	ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+) error
+
+// ChangePrimary calls "patronictl failover" against the Pod underlying e so
+// that replica becomes the new leader and current steps down.
+func (e Executor) ChangePrimary(ctx context.Context, current, replica string) error {
+	var stdout, stderr bytes.Buffer
+
+	err := e(ctx, nil, &stdout, &stderr,
+		"patronictl", "failover", "--force",
+		"--candidate", replica,
+		"--leader", current,
+	)
+	if err != nil {
+		return fmt.Errorf("patroni failover: %w (%q)", err, stderr.String())
+	}
+	return nil
+}
+
+// Pause quiesces Patroni's automatic failover by sending a
+// "PATCH /config" request with "pause: true" to the local REST API. It is
+// idempotent, so callers may invoke it repeatedly while waiting for a
+// shutdown to complete.
+func (e Executor) Pause(ctx context.Context) error {
+	var stdout, stderr bytes.Buffer
+
+	err := e(ctx, nil, &stdout, &stderr,
+		"curl", "--silent", "--insecure", "--request", "PATCH",
+		"--data", `{"pause":true}`,
+		"https://localhost:8008/config",
+	)
+	if err != nil {
+		return fmt.Errorf("patroni pause: %w (%q)", err, stderr.String())
+	}
+	return nil
+}
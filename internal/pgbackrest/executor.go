@@ -0,0 +1,54 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package pgbackrest drives the pgBackRest client inside a running Postgres
+// instance Pod.
+package pgbackrest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Executor runs pgbackrest commands against a single Postgres instance Pod,
+// typically by execing into its database container.
+type Executor func(
+	ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+) error
+
+// BackupType selects the kind of backup pgBackRest takes.
+type BackupType string
+
+const (
+	BackupTypeFull BackupType = "full"
+	BackupTypeDiff BackupType = "diff"
+)
+
+// Backup runs "pgbackrest backup" against the Pod underlying e.
+func (e Executor) Backup(ctx context.Context, repoName string, backupType BackupType) error {
+	var stdout, stderr bytes.Buffer
+
+	err := e(ctx, nil, &stdout, &stderr,
+		"pgbackrest", "backup",
+		"--repo="+repoName,
+		"--type="+string(backupType),
+	)
+	if err != nil {
+		return fmt.Errorf("pgbackrest backup: %w (%q)", err, stderr.String())
+	}
+	return nil
+}
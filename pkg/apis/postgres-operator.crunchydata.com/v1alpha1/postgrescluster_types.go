@@ -0,0 +1,142 @@
+/*
+ Copyright 2021 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresClusterSpec defines the desired state of a PostgresCluster.
+type PostgresClusterSpec struct {
+	// Image is the container image to use for PostgreSQL instances.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// PostgresVersion is the major version of PostgreSQL to run.
+	// +kubebuilder:validation:Required
+	PostgresVersion int `json:"postgresVersion"`
+
+	// Instances defines the instance sets that make up this cluster.
+	// +optional
+	Instances []PostgresInstanceSetSpec `json:"instances,omitempty"`
+
+	// DeletionPolicy controls which Kubernetes objects the operator leaves
+	// behind when this PostgresCluster is deleted. It defaults to deleting
+	// everything the operator created.
+	// +optional
+	DeletionPolicy *PostgresClusterDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// BackupBeforeDelete controls whether the operator takes a final
+	// pgBackRest backup of the primary before tearing a cluster down.
+	// Defaults to Never.
+	// +optional
+	// +kubebuilder:validation:Enum=Never;IfPossible;Required
+	BackupBeforeDelete BackupBeforeDeleteMode `json:"backupBeforeDelete,omitempty"`
+}
+
+// BackupBeforeDeleteMode controls whether and how a final pgBackRest backup
+// is taken before a PostgresCluster is torn down.
+type BackupBeforeDeleteMode string
+
+const (
+	// BackupBeforeDeleteNever never takes a final backup; this is the
+	// default.
+	BackupBeforeDeleteNever BackupBeforeDeleteMode = "Never"
+
+	// BackupBeforeDeleteIfPossible attempts a final backup but proceeds
+	// with deletion even if it fails, recording an event.
+	BackupBeforeDeleteIfPossible BackupBeforeDeleteMode = "IfPossible"
+
+	// BackupBeforeDeleteRequired blocks deletion until a final backup
+	// succeeds.
+	BackupBeforeDeleteRequired BackupBeforeDeleteMode = "Required"
+)
+
+// PostgresClusterDeletionPolicy lets an operator opt individual kinds of
+// object out of the teardown that normally accompanies deleting a
+// PostgresCluster. This is useful for disaster-recovery and re-adopt
+// workflows where the CR should go away but the data should not.
+type PostgresClusterDeletionPolicy struct {
+	// PreserveOnDelete, when true, is shorthand for setting every retain
+	// flag below to true. It takes precedence over the individual fields.
+	// +optional
+	PreserveOnDelete bool `json:"preserveOnDelete,omitempty"`
+
+	// RetainPVCs keeps the PersistentVolumeClaims backing each instance's
+	// data directory instead of deleting them, so the underlying volumes
+	// (and their data) survive the cluster.
+	// +optional
+	RetainPVCs bool `json:"retainPVCs,omitempty"`
+
+	// RetainSecrets keeps the Secrets the operator generated for this
+	// cluster, such as backup credentials, TLS certificates, and the
+	// replication user's password.
+	// +optional
+	RetainSecrets bool `json:"retainSecrets,omitempty"`
+
+	// RetainPatroniDCS keeps the Endpoints/ConfigMaps Patroni uses as its
+	// distributed configuration store, allowing a re-created cluster to
+	// rejoin the same Patroni history.
+	// +optional
+	RetainPatroniDCS bool `json:"retainPatroniDCS,omitempty"`
+}
+
+// PostgresInstanceSetSpec defines one set of PostgreSQL instances within a
+// cluster, such as the primary set or a read-replica set.
+type PostgresInstanceSetSpec struct {
+	// Name of this instance set.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Replicas is the number of desired Pods in this instance set.
+	// +kubebuilder:default=1
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// VolumeClaimSpec describes the PVC each instance Pod mounts for its
+	// PostgreSQL data directory.
+	// +optional
+	VolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec,omitempty"`
+}
+
+// PostgresClusterStatus defines the observed state of a PostgresCluster.
+type PostgresClusterStatus struct {
+	// Conditions represent the observed state of the cluster, such as the
+	// progress of a final backup before deletion.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// PostgresCluster is the Schema for the postgresclusters API.
+type PostgresCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresClusterSpec   `json:"spec,omitempty"`
+	Status PostgresClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresClusterList contains a list of PostgresCluster.
+type PostgresClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresCluster `json:"items"`
+}